@@ -0,0 +1,111 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Entry describes one logical backend name in backends.yaml: either the
+// built-in "gemini" backend, or an external binary speaking the
+// TextureBackend gRPC service (a Go-only JSON-over-gRPC contract, not
+// protobuf — see grpc.go, external.go).
+type Entry struct {
+	Type   string `yaml:"type"` // "builtin" or "external"
+	Binary string `yaml:"binary,omitempty"`
+	Model  string `yaml:"model,omitempty"`
+}
+
+// Registry maps logical backend names (as passed to --backend) to Entry
+// configs, loaded from a backends.yaml file.
+type Registry struct {
+	Backends map[string]Entry `yaml:"backends"`
+}
+
+// DefaultRegistry is used when no backends.yaml is found: just the built-in
+// Gemini backend under its conventional name.
+func DefaultRegistry() *Registry {
+	return &Registry{Backends: map[string]Entry{
+		"gemini": {Type: "builtin"},
+	}}
+}
+
+// LoadRegistry reads a backends.yaml file. If path doesn't exist,
+// DefaultRegistry is returned instead of an error, since backends.yaml is
+// optional.
+func LoadRegistry(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return DefaultRegistry(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var reg Registry
+	if err := yaml.Unmarshal(data, &reg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if reg.Backends == nil {
+		reg.Backends = map[string]Entry{}
+	}
+	if _, ok := reg.Backends["gemini"]; !ok {
+		reg.Backends["gemini"] = Entry{Type: "builtin"}
+	}
+	return &reg, nil
+}
+
+// AutoDetect scans PATH for backend-* binaries (see DetectOnPATH) and
+// registers any that aren't already in the registry as external backends
+// with no explicit Binary/Model set, so they're picked up at startup (e.g.
+// by --serve) instead of only resolving on first use via Dial's fallback.
+// It returns the logical names that were newly added.
+func (r *Registry) AutoDetect() []string {
+	var added []string
+	for _, name := range DetectOnPATH() {
+		if _, ok := r.Backends[name]; ok {
+			continue
+		}
+		r.Backends[name] = Entry{Type: "external"}
+		added = append(added, name)
+	}
+	return added
+}
+
+// Dial resolves name to a Backend, either the built-in Gemini backend
+// (apiKey is forwarded to it) or an external binary dialed over gRPC. If
+// name isn't in the registry, Dial falls back to auto-detecting a
+// "backend-<name>" binary on PATH, the same convention LocalAI uses for
+// unregistered model backends.
+func (r *Registry) Dial(ctx context.Context, name, apiKey string) (Backend, error) {
+	entry, ok := r.Backends[name]
+	if !ok {
+		binaryPath, err := findOnPath(name)
+		if err != nil {
+			return nil, fmt.Errorf("unknown backend %q: not in registry and no backend-%s on PATH", name, name)
+		}
+		return DialExternal(ctx, binaryPath, name)
+	}
+
+	switch entry.Type {
+	case "", "builtin":
+		if name != "gemini" {
+			return nil, fmt.Errorf("backend %q has type %q but no built-in implementation exists", name, entry.Type)
+		}
+		return &GeminiBackend{APIKey: apiKey}, nil
+	case "external":
+		binaryPath := entry.Binary
+		if binaryPath == "" {
+			var err error
+			binaryPath, err = findOnPath(name)
+			if err != nil {
+				return nil, fmt.Errorf("backend %q has no binary configured and no backend-%s on PATH", name, name)
+			}
+		}
+		return DialExternal(ctx, binaryPath, entry.Model)
+	default:
+		return nil, fmt.Errorf("backend %q has unknown type %q", name, entry.Type)
+	}
+}
@@ -0,0 +1,130 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// externalBackendPrefix is the convention external backend binaries must
+// follow to be auto-detected on PATH, e.g. "backend-sdxl" or
+// "backend-comfyui". Auto-detection is only used when a registry entry
+// doesn't set an explicit Binary path.
+const externalBackendPrefix = "backend-"
+
+// ExternalBackend spawns a backend-* binary and talks to it over gRPC on a
+// unix socket, so users can plug in local models (SDXL, ComfyUI, ...)
+// without bs-case linking against them directly.
+type ExternalBackend struct {
+	cmd    *exec.Cmd
+	conn   *grpc.ClientConn
+	client *textureBackendClient
+}
+
+// DialExternal launches binaryPath with `--socket <path> --model <modelID>`,
+// waits for it to create the socket, and dials it over gRPC.
+func DialExternal(ctx context.Context, binaryPath, modelID string) (*ExternalBackend, error) {
+	socketPath := filepath.Join(os.TempDir(), fmt.Sprintf("bs-case-%d.sock", time.Now().UnixNano()))
+	os.Remove(socketPath)
+
+	cmd := exec.Command(binaryPath, "--socket", socketPath, "--model", modelID)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start external backend %s: %w", binaryPath, err)
+	}
+
+	if err := waitForSocket(socketPath, 10*time.Second); err != nil {
+		cmd.Process.Kill()
+		return nil, fmt.Errorf("external backend %s did not open socket %s: %w", binaryPath, socketPath, err)
+	}
+
+	conn, err := grpc.NewClient("unix://"+socketPath,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.CallContentSubtype(jsonCodecName)),
+	)
+	if err != nil {
+		cmd.Process.Kill()
+		return nil, fmt.Errorf("failed to dial external backend %s: %w", binaryPath, err)
+	}
+
+	return &ExternalBackend{
+		cmd:    cmd,
+		conn:   conn,
+		client: &textureBackendClient{conn: conn},
+	}, nil
+}
+
+func (b *ExternalBackend) GenerateTexture(ctx context.Context, req *GenerateTextureRequest) (*GenerateTextureResponse, error) {
+	return b.client.GenerateTexture(ctx, req)
+}
+
+// Close tears down the gRPC connection and the backend process.
+func (b *ExternalBackend) Close() error {
+	b.conn.Close()
+	if b.cmd.Process != nil {
+		b.cmd.Process.Kill()
+	}
+	return b.cmd.Wait()
+}
+
+func waitForSocket(path string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(path); err == nil {
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return fmt.Errorf("timed out waiting for socket")
+}
+
+// findOnPath looks for a binary named backend-<name> on PATH, for registry
+// entries that don't set an explicit Binary.
+func findOnPath(name string) (string, error) {
+	return exec.LookPath(externalBackendPrefix + name)
+}
+
+// DetectOnPATH scans every directory in $PATH for executables named
+// "backend-<name>" and returns the logical names found (without the
+// prefix), de-duplicated and sorted. It's how Registry.AutoDetect finds
+// backends at startup instead of only resolving them lazily in Dial.
+func DetectOnPATH() []string {
+	seen := map[string]bool{}
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasPrefix(name, externalBackendPrefix) {
+				continue
+			}
+			logical := strings.TrimPrefix(name, externalBackendPrefix)
+			if logical == "" {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+				continue
+			}
+			seen[logical] = true
+		}
+	}
+
+	found := make([]string, 0, len(seen))
+	for name := range seen {
+		found = append(found, name)
+	}
+	sort.Strings(found)
+	return found
+}
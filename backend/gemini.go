@@ -0,0 +1,68 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/genai"
+)
+
+// geminiModelName is the Nano Banana Pro (Gemini 3 Pro Image) model used for
+// higher quality textures.
+const geminiModelName = "gemini-3-pro-image-preview"
+
+// GeminiBackend is the built-in Backend that talks to Nano Banana Pro
+// directly; it runs in-process rather than over gRPC since it's the default.
+type GeminiBackend struct {
+	APIKey string
+}
+
+func (b *GeminiBackend) GenerateTexture(ctx context.Context, req *GenerateTextureRequest) (*GenerateTextureResponse, error) {
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: b.APIKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	var parts []*genai.Part
+	parts = append(parts, &genai.Part{Text: req.Prompt})
+
+	for _, ref := range req.RefImages {
+		mimeType := ref.MimeType
+		if mimeType == "" {
+			mimeType = "image/png"
+		}
+		parts = append(parts, &genai.Part{
+			InlineData: &genai.Blob{
+				MIMEType: mimeType,
+				Data:     ref.Data,
+			},
+		})
+	}
+
+	contents := []*genai.Content{{Parts: parts}}
+	config := &genai.GenerateContentConfig{
+		ImageConfig: &genai.ImageConfig{
+			AspectRatio: req.AspectRatio,
+			ImageSize:   req.Size,
+		},
+	}
+
+	resp, err := client.Models.GenerateContent(ctx, geminiModelName, contents, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return nil, fmt.Errorf("no content generated")
+	}
+
+	part := resp.Candidates[0].Content.Parts[0]
+	if part.InlineData == nil {
+		return nil, fmt.Errorf("no inline data found in response part")
+	}
+
+	return &GenerateTextureResponse{
+		Data:     part.InlineData.Data,
+		MimeType: part.InlineData.MIMEType,
+	}, nil
+}
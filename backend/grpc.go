@@ -0,0 +1,73 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// jsonCodecName is registered with grpc so that external backends can be
+// plain Go binaries speaking JSON-over-gRPC instead of requiring a full
+// protoc-gen-go toolchain. This is NOT a standard protobuf wire format: a
+// backend-* binary written in another language (or using a protoc-generated
+// client) will not be able to talk to TextureBackendServiceDesc. It must
+// import this package (or reimplement this exact codec/dispatch) and
+// exchange GenerateTextureRequest/Response as JSON, not protobuf bytes.
+const jsonCodecName = "json"
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return jsonCodecName }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+const textureBackendGenerateTextureMethod = "/backend.TextureBackend/GenerateTexture"
+
+// textureBackendClient dials a TextureBackend gRPC service (see the
+// jsonCodecName doc comment for the wire-format contract) and implements
+// Backend by invoking GenerateTexture.
+type textureBackendClient struct {
+	conn *grpc.ClientConn
+}
+
+func (c *textureBackendClient) GenerateTexture(ctx context.Context, req *GenerateTextureRequest) (*GenerateTextureResponse, error) {
+	resp := new(GenerateTextureResponse)
+	if err := c.conn.Invoke(ctx, textureBackendGenerateTextureMethod, req, resp, grpc.CallContentSubtype(jsonCodecName)); err != nil {
+		return nil, fmt.Errorf("GenerateTexture rpc failed: %w", err)
+	}
+	return resp, nil
+}
+
+// TextureBackendServiceDesc lets an external backend binary register itself
+// as a TextureBackend server with grpc.NewServer().RegisterService.
+var TextureBackendServiceDesc = grpc.ServiceDesc{
+	ServiceName: "backend.TextureBackend",
+	HandlerType: (*Backend)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GenerateTexture",
+			Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(GenerateTextureRequest)
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return srv.(Backend).GenerateTexture(ctx, req)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: textureBackendGenerateTextureMethod}
+				handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+					return srv.(Backend).GenerateTexture(ctx, req.(*GenerateTextureRequest))
+				}
+				return interceptor(ctx, req, info, handler)
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
@@ -0,0 +1,38 @@
+package backend
+
+import "context"
+
+// RefImage is one reference image part of a GenerateTextureRequest. A
+// multi-page PDF reference (see pdfinput) becomes one RefImage per page.
+type RefImage struct {
+	Data     []byte `json:"data"`
+	MimeType string `json:"mime_type"`
+}
+
+// GenerateTextureRequest is the body of the GenerateTexture RPC request.
+// There is no .proto for this: external backends are dialed as plain
+// gRPC-Go servers using the JSON content-subtype codec registered in
+// grpc.go (see TextureBackendServiceDesc), not a protoc-generated protobuf
+// stub, so an external backend binary must import this package (or
+// reimplement the same JSON codec and method dispatch) rather than use a
+// protoc-generated client.
+type GenerateTextureRequest struct {
+	Prompt      string     `json:"prompt"`
+	RefImages   []RefImage `json:"ref_images,omitempty"`
+	AspectRatio string     `json:"aspect_ratio,omitempty"`
+	Size        string     `json:"size,omitempty"`
+}
+
+// GenerateTextureResponse is the body of the GenerateTexture RPC response
+// (see the GenerateTextureRequest doc comment for the wire-format caveat).
+type GenerateTextureResponse struct {
+	Data     []byte `json:"data"`
+	MimeType string `json:"mime_type"`
+}
+
+// Backend is implemented by anything that can turn a prompt (and optional
+// reference image) into texture bytes. gemini.go implements it in-process;
+// external.go implements it by dialing a backend-* binary over gRPC.
+type Backend interface {
+	GenerateTexture(ctx context.Context, req *GenerateTextureRequest) (*GenerateTextureResponse, error)
+}
@@ -0,0 +1,52 @@
+// Command backend-sdxl is a reference stub for an external bs-case image
+// backend. It speaks bs-case's TextureBackend gRPC service (see
+// backend/grpc.go) over a unix socket — a JSON-over-gRPC contract specific
+// to the backend package, not portable protobuf, so this stub imports that
+// package rather than a generated client. Point --backend sdxl (with a
+// matching entry in backends.yaml, or this binary on PATH) at it once
+// GenerateTexture below is wired up to a real SDXL/ComfyUI deployment.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+
+	"github.com/ha5ky/bs-case/backend"
+	"google.golang.org/grpc"
+)
+
+type sdxlBackend struct {
+	model string
+}
+
+func (b *sdxlBackend) GenerateTexture(ctx context.Context, req *backend.GenerateTextureRequest) (*backend.GenerateTextureResponse, error) {
+	return nil, fmt.Errorf("backend-sdxl is a stub: wire GenerateTexture up to model %q", b.model)
+}
+
+func main() {
+	socketPath := flag.String("socket", "", "unix socket path to listen on (set by bs-case)")
+	model := flag.String("model", "", "model id to load")
+	flag.Parse()
+
+	if *socketPath == "" {
+		log.Fatal("--socket is required")
+	}
+
+	os.Remove(*socketPath)
+	lis, err := net.Listen("unix", *socketPath)
+	if err != nil {
+		log.Fatalf("failed to listen on %s: %v", *socketPath, err)
+	}
+
+	srv := grpc.NewServer()
+	srv.RegisterService(&backend.TextureBackendServiceDesc, &sdxlBackend{model: *model})
+
+	log.Printf("backend-sdxl listening on %s (model=%s)", *socketPath, *model)
+	if err := srv.Serve(lis); err != nil {
+		log.Fatalf("serve failed: %v", err)
+	}
+}
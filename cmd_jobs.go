@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ha5ky/bs-case/backend"
+	"github.com/ha5ky/bs-case/job"
+)
+
+// openJobStore opens the configured job.Store backend. sqlite is the
+// default and the only one implemented so far; redis/rabbitmq dial out to
+// their respective stubs (see job/external.go) and return a clear error
+// until those drivers are wired up.
+func openJobStore(backendName, dbPath, redisAddr, rabbitURL string) (job.Store, error) {
+	switch backendName {
+	case "", "sqlite":
+		return job.OpenSQLite(dbPath)
+	case "redis":
+		return job.OpenRedis(redisAddr)
+	case "rabbitmq":
+		return job.OpenRabbitMQ(rabbitURL)
+	default:
+		return nil, fmt.Errorf("unknown --jobs_backend %q (want sqlite, redis, or rabbitmq)", backendName)
+	}
+}
+
+// runEnqueue implements `bs-case enqueue ...`: persists a queued Job and
+// prints its id, without blocking on generation or rendering.
+func runEnqueue(args []string) {
+	fs := flag.NewFlagSet("enqueue", flag.ExitOnError)
+	prompt := fs.String("prompt", "", "Prompt for texture generation")
+	backendName := fs.String("backend", "gemini", "Image-generation backend to use (see backends.yaml)")
+	aspectRatio := fs.String("aspect_ratio", "1:1", "Aspect ratio for generated image (e.g., 1:1, 16:9, 4:3)")
+	imageSize := fs.String("image_size", "1K", "Image size/resolution (e.g., 1K, 2K)")
+	modelPath := fs.String("input", "1.glb", "Path to the 3D model file (obj, fbx, glb, etc.)")
+	blenderPath := fs.String("blender", "", "Path to Blender executable (omit to only generate the texture)")
+	frames := fs.Int("frames", 0, "Number of frames to render (0 = auto/from file)")
+	rotations := fs.Float64("rotations", -1.0, "Number of full rotations (-1 = auto/from file)")
+	jobsDB := fs.String("jobs_db", "jobs.db", "Path to the sqlite jobs database")
+	jobsBackend := fs.String("jobs_backend", "sqlite", "Job store backend: sqlite, redis, or rabbitmq")
+	fs.Parse(args)
+
+	if *prompt == "" {
+		log.Fatal("enqueue: --prompt is required")
+	}
+
+	store, err := openJobStore(*jobsBackend, *jobsDB, "", "")
+	if err != nil {
+		log.Fatalf("Failed to open job store: %v", err)
+	}
+	defer store.Close()
+
+	now := time.Now().Unix()
+	j := &job.Job{
+		ID:          fmt.Sprintf("job_%x", time.Now().UnixNano()),
+		State:       job.StateQueued,
+		Prompt:      *prompt,
+		PromptHash:  job.HashPrompt(*prompt),
+		Backend:     *backendName,
+		AspectRatio: *aspectRatio,
+		ImageSize:   *imageSize,
+		ModelPath:   *modelPath,
+		BlenderPath: *blenderPath,
+		Frames:      *frames,
+		Rotations:   *rotations,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := store.Create(context.Background(), j); err != nil {
+		log.Fatalf("Failed to enqueue job: %v", err)
+	}
+	fmt.Println(j.ID)
+}
+
+// runStatus implements `bs-case status <id>`.
+func runStatus(args []string) {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	jobsDB := fs.String("jobs_db", "jobs.db", "Path to the sqlite jobs database")
+	jobsBackend := fs.String("jobs_backend", "sqlite", "Job store backend: sqlite, redis, or rabbitmq")
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		log.Fatal("status: usage: bs-case status <id>")
+	}
+	id := fs.Arg(0)
+
+	store, err := openJobStore(*jobsBackend, *jobsDB, "", "")
+	if err != nil {
+		log.Fatalf("Failed to open job store: %v", err)
+	}
+	defer store.Close()
+
+	j, err := store.Get(context.Background(), id)
+	if err != nil {
+		log.Fatalf("Failed to get job %s: %v", id, err)
+	}
+	fmt.Printf("id:     %s\nstate:  %s\nprompt: %s\noutput: %s\n", j.ID, j.State, j.Prompt, j.OutputPath)
+	if j.Error != "" {
+		fmt.Printf("error:  %s\n", j.Error)
+	}
+}
+
+// runCancel implements `bs-case cancel <id>`.
+func runCancel(args []string) {
+	fs := flag.NewFlagSet("cancel", flag.ExitOnError)
+	jobsDB := fs.String("jobs_db", "jobs.db", "Path to the sqlite jobs database")
+	jobsBackend := fs.String("jobs_backend", "sqlite", "Job store backend: sqlite, redis, or rabbitmq")
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		log.Fatal("cancel: usage: bs-case cancel <id>")
+	}
+	id := fs.Arg(0)
+
+	store, err := openJobStore(*jobsBackend, *jobsDB, "", "")
+	if err != nil {
+		log.Fatalf("Failed to open job store: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.Cancel(context.Background(), id); err != nil {
+		log.Fatalf("Failed to cancel job %s: %v", id, err)
+	}
+	fmt.Printf("cancelled %s\n", id)
+}
+
+// runWorker implements `bs-case worker`: drains the queue until interrupted,
+// so multiple workers can fan out over the same job store and render
+// different jobs concurrently.
+func runWorker(args []string) {
+	fs := flag.NewFlagSet("worker", flag.ExitOnError)
+	jobsDB := fs.String("jobs_db", "jobs.db", "Path to the sqlite jobs database")
+	jobsBackend := fs.String("jobs_backend", "sqlite", "Job store backend: sqlite, redis, or rabbitmq")
+	apiKey := fs.String("api_key", "", "API key forwarded to the generation backend")
+	backendsConfig := fs.String("backends_config", "backends.yaml", "Path to the backend registry file")
+	pollInterval := fs.Duration("poll_interval", time.Second, "How often to poll for new jobs when the queue is empty")
+	fs.Parse(args)
+
+	reg, err := backend.LoadRegistry(*backendsConfig)
+	if err != nil {
+		log.Fatalf("Failed to load backend registry: %v", err)
+	}
+	if detected := reg.AutoDetect(); len(detected) > 0 {
+		fmt.Printf("Auto-detected backends on PATH: %v\n", detected)
+	}
+	backendRegistry = reg
+
+	store, err := openJobStore(*jobsBackend, *jobsDB, "", "")
+	if err != nil {
+		log.Fatalf("Failed to open job store: %v", err)
+	}
+	defer store.Close()
+
+	w := job.NewWorker(store, newJobProcessor(*apiKey, nil), *pollInterval)
+	fmt.Println("Worker draining queue, Ctrl-C to stop...")
+	if err := w.Run(context.Background()); err != nil {
+		log.Fatalf("Worker stopped: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+// Package gallery implements bs-case's template system: YAML files that
+// describe a card texture (a Go-template prompt, the slot it fills, and the
+// generation params to use) so non-technical users can run
+// `bs-case --template tarot-major-arcana --var Subject=Fool` instead of
+// crafting a prompt by hand.
+package gallery
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Template describes one installable card template.
+type Template struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Prompt is a Go text/template string; Render fills in {{.Subject}},
+	// {{.Style}}, etc. from the --var flags.
+	Prompt string `yaml:"prompt"`
+	// Slot is which texture this template fills: front, back, or background.
+	Slot string `yaml:"slot"`
+	// NeedsRefImage marks templates that require --image_input to be set.
+	NeedsRefImage bool   `yaml:"needs_ref_image"`
+	AspectRatio   string `yaml:"aspect_ratio"`
+	ImageSize     string `yaml:"image_size"`
+	// PostProcess lists post-processing steps to run on the generated
+	// texture, in the same syntax as the --pp flag.
+	PostProcess []string `yaml:"post_process"`
+}
+
+// Render fills Prompt's {{.Key}} placeholders from vars. Vars the caller
+// didn't pass with --var are left as the zero value (so "{{if .Style}}...
+// {{end}}" can treat them as optional) rather than erroring out, matching
+// shipped templates like tarot-major-arcana.yaml that rely on that.
+func (t Template) Render(vars map[string]string) (string, error) {
+	tmpl, err := template.New(t.Name).Option("missingkey=zero").Parse(t.Prompt)
+	if err != nil {
+		return "", fmt.Errorf("template %q: invalid prompt template: %w", t.Name, err)
+	}
+
+	data := make(map[string]string, len(vars))
+	for k, v := range vars {
+		data[k] = v
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("template %q: %w", t.Name, err)
+	}
+	return buf.String(), nil
+}
+
+// LoadDir reads every *.yaml/*.yml file in dir as a Template.
+func LoadDir(dir string) ([]Template, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gallery dir %s: %w", dir, err)
+	}
+
+	var templates []Template
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		if ext != ".yaml" && ext != ".yml" {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+
+		var t Template
+		if err := yaml.Unmarshal(data, &t); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", entry.Name(), err)
+		}
+		if t.Name == "" {
+			t.Name = strings.TrimSuffix(entry.Name(), ext)
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// Find looks up a single template by name across one or more gallery
+// directories, returning the first match.
+func Find(dirs []string, name string) (Template, error) {
+	for _, dir := range dirs {
+		templates, err := LoadDir(dir)
+		if err != nil {
+			return Template{}, err
+		}
+		for _, t := range templates {
+			if t.Name == name {
+				return t, nil
+			}
+		}
+	}
+	return Template{}, fmt.Errorf("template %q not found in %v", name, dirs)
+}
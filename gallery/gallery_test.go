@@ -0,0 +1,42 @@
+package gallery
+
+import "testing"
+
+func TestTemplateRender(t *testing.T) {
+	tmpl := Template{
+		Name:   "tarot-major-arcana",
+		Prompt: "A tarot card of {{.Subject}}{{if .Style}}, in {{.Style}} style{{end}}",
+	}
+
+	got, err := tmpl.Render(map[string]string{"Subject": "The Fool", "Style": "art deco"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "A tarot card of The Fool, in art deco style"
+	if got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestTemplateRenderOptionalVarsAreLeftZero(t *testing.T) {
+	tmpl := Template{
+		Name:   "tarot-major-arcana",
+		Prompt: "A tarot card of {{.Subject}}{{if .Style}}, in {{.Style}} style{{end}}",
+	}
+
+	got, err := tmpl.Render(map[string]string{"Subject": "The Fool"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "A tarot card of The Fool"
+	if got != want {
+		t.Errorf("Render() with missing --var = %q, want %q", got, want)
+	}
+}
+
+func TestTemplateRenderInvalidTemplate(t *testing.T) {
+	tmpl := Template{Name: "broken", Prompt: "{{.Subject"}
+	if _, err := tmpl.Render(nil); err == nil {
+		t.Error("Render(): expected error for malformed template, got none")
+	}
+}
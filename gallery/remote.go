@@ -0,0 +1,62 @@
+package gallery
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// allowedRemoteSchemes are the URL schemes FetchRemote will clone. Anything
+// else (including a bare "-" prefixed string, which git/exec would otherwise
+// happily parse as a flag instead of a URL) is rejected.
+var allowedRemoteSchemes = []string{"https://", "git://", "ssh://"}
+
+// validateRemoteURL rejects anything that isn't an allow-listed git URL, so a
+// caller-supplied "remote" can't smuggle a git option (e.g.
+// "--upload-pack=...") into the argv of the git subprocess FetchRemote runs.
+func validateRemoteURL(url string) error {
+	if strings.HasPrefix(url, "-") {
+		return fmt.Errorf("invalid gallery remote %q: must not start with '-'", url)
+	}
+	for _, scheme := range allowedRemoteSchemes {
+		if strings.HasPrefix(url, scheme) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid gallery remote %q: must start with one of %v", url, allowedRemoteSchemes)
+}
+
+// FetchRemote clones a git repository of templates into cacheDir, the same
+// "point at a git URL" pattern LocalAI's model gallery uses, so users can
+// install third-party template packs without vendoring them into bs-case
+// itself. If cacheDir already holds a clone of url, it's updated in place.
+func FetchRemote(url, cacheDir string) error {
+	if err := validateRemoteURL(url); err != nil {
+		return err
+	}
+
+	gitDir := filepath.Join(cacheDir, ".git")
+	if _, err := os.Stat(gitDir); err == nil {
+		cmd := exec.Command("git", "-C", cacheDir, "pull", "--ff-only")
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to update gallery clone at %s: %w", cacheDir, err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cacheDir), 0755); err != nil {
+		return fmt.Errorf("failed to create gallery cache dir: %w", err)
+	}
+
+	cmd := exec.Command("git", "clone", "--depth", "1", "--", url, cacheDir)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to clone gallery %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package gallery
+
+import "testing"
+
+func TestValidateRemoteURL(t *testing.T) {
+	valid := []string{
+		"https://github.com/example/templates.git",
+		"git://example.com/templates.git",
+		"ssh://git@example.com/templates.git",
+	}
+	for _, url := range valid {
+		if err := validateRemoteURL(url); err != nil {
+			t.Errorf("validateRemoteURL(%q): unexpected error: %v", url, err)
+		}
+	}
+
+	invalid := []string{
+		"-upload-pack=touch /tmp/pwned",
+		"--upload-pack=touch /tmp/pwned",
+		"file:///etc/passwd",
+		"/local/path",
+		"",
+	}
+	for _, url := range invalid {
+		if err := validateRemoteURL(url); err == nil {
+			t.Errorf("validateRemoteURL(%q): expected error, got none", url)
+		}
+	}
+}
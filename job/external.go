@@ -0,0 +1,53 @@
+package job
+
+import (
+	"context"
+	"fmt"
+)
+
+// RedisStore and RabbitMQStore are placeholders for the optional non-SQLite
+// drivers: a Redis-backed Store for sharing a queue across hosts without a
+// shared filesystem, and a RabbitMQ-backed Store for when workers should
+// consume from a proper message broker instead of polling. SQLiteStore
+// covers the default single-host case; wire these up (same Store
+// interface) when a deployment actually needs one.
+
+type RedisStore struct {
+	addr string
+}
+
+// OpenRedis is a stub: dial and implement Store against a real Redis client
+// (e.g. go-redis) when multi-host job sharing is needed.
+func OpenRedis(addr string) (*RedisStore, error) {
+	return nil, fmt.Errorf("redis job store not yet implemented (addr=%s); use --jobs_backend sqlite", addr)
+}
+
+func (s *RedisStore) Create(ctx context.Context, j *Job) error         { return errNotImplemented }
+func (s *RedisStore) Get(ctx context.Context, id string) (*Job, error) { return nil, errNotImplemented }
+func (s *RedisStore) Update(ctx context.Context, j *Job) error         { return errNotImplemented }
+func (s *RedisStore) List(ctx context.Context) ([]*Job, error)         { return nil, errNotImplemented }
+func (s *RedisStore) Dequeue(ctx context.Context) (*Job, error)        { return nil, errNotImplemented }
+func (s *RedisStore) Cancel(ctx context.Context, id string) error      { return errNotImplemented }
+func (s *RedisStore) Close() error                                     { return nil }
+
+type RabbitMQStore struct {
+	url string
+}
+
+// OpenRabbitMQ is a stub: connect and implement Store against a real AMQP
+// client (e.g. amqp091-go) when a broker-backed queue is needed.
+func OpenRabbitMQ(url string) (*RabbitMQStore, error) {
+	return nil, fmt.Errorf("rabbitmq job store not yet implemented (url=%s); use --jobs_backend sqlite", url)
+}
+
+func (s *RabbitMQStore) Create(ctx context.Context, j *Job) error { return errNotImplemented }
+func (s *RabbitMQStore) Get(ctx context.Context, id string) (*Job, error) {
+	return nil, errNotImplemented
+}
+func (s *RabbitMQStore) Update(ctx context.Context, j *Job) error    { return errNotImplemented }
+func (s *RabbitMQStore) List(ctx context.Context) ([]*Job, error)    { return nil, errNotImplemented }
+func (s *RabbitMQStore) Dequeue(ctx context.Context) (*Job, error)   { return nil, errNotImplemented }
+func (s *RabbitMQStore) Cancel(ctx context.Context, id string) error { return errNotImplemented }
+func (s *RabbitMQStore) Close() error                                { return nil }
+
+const errNotImplemented = errString("job: backend not yet implemented")
@@ -0,0 +1,47 @@
+// Package job implements bs-case's async job queue: a texture-generation +
+// render request becomes a persisted Job that survives process restarts and
+// can be polled instead of blocking the caller on a single long-running
+// Blender invocation.
+package job
+
+// State is a Job's place in its lifecycle.
+type State string
+
+const (
+	StateQueued     State = "queued"
+	StateGenerating State = "generating"
+	StateRendering  State = "rendering"
+	StateDone       State = "done"
+	StateFailed     State = "failed"
+	StateCancelled  State = "cancelled"
+)
+
+// Job records one texture-generation + render request end to end, so a
+// crash mid-render doesn't lose progress and callers can poll status.
+type Job struct {
+	ID     string
+	State  State
+	Prompt string
+	// PromptHash and ResponseHash let callers/operators tell whether a
+	// re-run would hit the same backend output without storing the full
+	// prompt/response bytes twice.
+	PromptHash   string
+	ResponseHash string
+
+	// Generation + render parameters needed to actually run the job.
+	Backend     string
+	AspectRatio string
+	ImageSize   string
+	ModelPath   string
+	BlenderPath string
+	Frames      int
+	Rotations   float64
+
+	// BlenderArgv records the argv the worker actually ran, once the
+	// texture's been generated and rendering has started.
+	BlenderArgv []string
+	OutputPath  string
+	Error       string
+	CreatedAt   int64
+	UpdatedAt   int64
+}
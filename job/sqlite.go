@@ -0,0 +1,204 @@
+package job
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStore is the default Store: a single jobs.db file so long renders
+// survive a restart without standing up Redis or RabbitMQ.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens (creating if necessary) a SQLite-backed Store at path.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite db %s: %w", path, err)
+	}
+	db.SetMaxOpenConns(1) // avoid SQLITE_BUSY from this process's own goroutines
+
+	const schema = `
+CREATE TABLE IF NOT EXISTS jobs (
+	id            TEXT PRIMARY KEY,
+	state         TEXT NOT NULL,
+	prompt        TEXT NOT NULL,
+	prompt_hash   TEXT NOT NULL,
+	response_hash TEXT NOT NULL,
+	backend       TEXT NOT NULL,
+	aspect_ratio  TEXT NOT NULL,
+	image_size    TEXT NOT NULL,
+	model_path    TEXT NOT NULL,
+	blender_path  TEXT NOT NULL,
+	frames        INTEGER NOT NULL,
+	rotations     REAL NOT NULL,
+	blender_argv  TEXT NOT NULL,
+	output_path   TEXT NOT NULL,
+	error         TEXT NOT NULL,
+	created_at    INTEGER NOT NULL,
+	updated_at    INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS jobs_state_created_at ON jobs(state, created_at);
+`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) Close() error { return s.db.Close() }
+
+func (s *SQLiteStore) Create(ctx context.Context, j *Job) error {
+	argv, err := json.Marshal(j.BlenderArgv)
+	if err != nil {
+		return fmt.Errorf("failed to marshal blender argv: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO jobs (id, state, prompt, prompt_hash, response_hash, backend, aspect_ratio, image_size, model_path, blender_path, frames, rotations, blender_argv, output_path, error, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		j.ID, j.State, j.Prompt, j.PromptHash, j.ResponseHash, j.Backend, j.AspectRatio, j.ImageSize, j.ModelPath, j.BlenderPath, j.Frames, j.Rotations, string(argv), j.OutputPath, j.Error, j.CreatedAt, j.UpdatedAt)
+	return err
+}
+
+const selectJobColumns = `id, state, prompt, prompt_hash, response_hash, backend, aspect_ratio, image_size, model_path, blender_path, frames, rotations, blender_argv, output_path, error, created_at, updated_at`
+
+func (s *SQLiteStore) Get(ctx context.Context, id string) (*Job, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+selectJobColumns+` FROM jobs WHERE id = ?`, id)
+	j, err := scanJob(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return j, err
+}
+
+// Update persists changes to an existing job, but only if it hasn't already
+// reached a terminal state (cancelled/done/failed) — so a worker racing a
+// concurrent Cancel can't clobber it back to generating/rendering/done. If
+// the job is already terminal, Update is a no-op and returns ErrCancelled.
+func (s *SQLiteStore) Update(ctx context.Context, j *Job) error {
+	argv, err := json.Marshal(j.BlenderArgv)
+	if err != nil {
+		return fmt.Errorf("failed to marshal blender argv: %w", err)
+	}
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE jobs SET state = ?, response_hash = ?, blender_argv = ?, output_path = ?, error = ?, updated_at = ?
+		WHERE id = ? AND state NOT IN (?, ?, ?)`,
+		j.State, j.ResponseHash, string(argv), j.OutputPath, j.Error, j.UpdatedAt, j.ID,
+		StateCancelled, StateDone, StateFailed)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrCancelled
+	}
+	return nil
+}
+
+func (s *SQLiteStore) List(ctx context.Context) ([]*Job, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+selectJobColumns+` FROM jobs ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// Dequeue claims the oldest queued job with a single conditional UPDATE, so
+// two workers racing on the same jobs.db can't both claim it.
+func (s *SQLiteStore) Dequeue(ctx context.Context) (*Job, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id string
+	err = tx.QueryRowContext(ctx, `SELECT id FROM jobs WHERE state = ? ORDER BY created_at LIMIT 1`, StateQueued).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, ErrNoJobs
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE jobs SET state = ? WHERE id = ? AND state = ?`, StateGenerating, id, StateQueued)
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrNoJobs // lost the race to another worker
+	}
+
+	row := tx.QueryRowContext(ctx, `SELECT `+selectJobColumns+` FROM jobs WHERE id = ?`, id)
+	j, err := scanJob(row)
+	if err != nil {
+		return nil, err
+	}
+	return j, tx.Commit()
+}
+
+func (s *SQLiteStore) Cancel(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE jobs SET state = ? WHERE id = ? AND state IN (?, ?, ?)`,
+		StateCancelled, id, StateQueued, StateGenerating, StateRendering)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanJob(row rowScanner) (*Job, error) {
+	var j Job
+	var argv string
+	if err := row.Scan(&j.ID, &j.State, &j.Prompt, &j.PromptHash, &j.ResponseHash, &j.Backend, &j.AspectRatio, &j.ImageSize, &j.ModelPath, &j.BlenderPath, &j.Frames, &j.Rotations, &argv, &j.OutputPath, &j.Error, &j.CreatedAt, &j.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if argv != "" {
+		if err := json.Unmarshal([]byte(argv), &j.BlenderArgv); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal blender argv for job %s: %w", j.ID, err)
+		}
+	}
+	return &j, nil
+}
+
+// HashPrompt is a stable, short identifier for a prompt, used so jobs can be
+// compared without storing (or logging) the full Gemini request/response.
+func HashPrompt(s string) string {
+	return fmt.Sprintf("%x", simpleHash(s))
+}
+
+// simpleHash is FNV-1a; it only needs to be stable and cheap, not
+// cryptographically strong.
+func simpleHash(s string) uint64 {
+	const offset64 = 14695981039346656037
+	const prime64 = 1099511628211
+	h := uint64(offset64)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= prime64
+	}
+	return h
+}
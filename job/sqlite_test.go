@@ -0,0 +1,96 @@
+package job
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func newTestStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, err := OpenSQLite(filepath.Join(t.TempDir(), "jobs.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func mustCreateJob(t *testing.T, store *SQLiteStore, id string) *Job {
+	t.Helper()
+	j := &Job{ID: id, State: StateQueued, Prompt: "a card", PromptHash: HashPrompt("a card")}
+	if err := store.Create(context.Background(), j); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return j
+}
+
+// TestDequeueIsExclusive exercises the race Dequeue's conditional UPDATE
+// guards against: many workers racing the same queued job should only ever
+// have exactly one of them win.
+func TestDequeueIsExclusive(t *testing.T) {
+	store := newTestStore(t)
+	mustCreateJob(t, store, "job_1")
+
+	const workers = 8
+	var wg sync.WaitGroup
+	wins := make(chan *Job, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			j, err := store.Dequeue(context.Background())
+			if err == ErrNoJobs {
+				return
+			}
+			if err != nil {
+				t.Errorf("Dequeue: %v", err)
+				return
+			}
+			wins <- j
+		}()
+	}
+	wg.Wait()
+	close(wins)
+
+	var got []*Job
+	for j := range wins {
+		got = append(got, j)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Dequeue: %d workers claimed the job, want exactly 1", len(got))
+	}
+	if got[0].ID != "job_1" {
+		t.Errorf("Dequeue claimed job %q, want job_1", got[0].ID)
+	}
+}
+
+// TestUpdateNoopsAfterCancel exercises the clobber this package's Update
+// guards against: once Cancel has moved a job to a terminal state, a worker
+// still mid-flight must not be able to overwrite that with Update.
+func TestUpdateNoopsAfterCancel(t *testing.T) {
+	store := newTestStore(t)
+	j := mustCreateJob(t, store, "job_1")
+
+	if _, err := store.Dequeue(context.Background()); err != nil {
+		t.Fatalf("Dequeue: %v", err)
+	}
+
+	if err := store.Cancel(context.Background(), j.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+
+	j.State = StateRendering
+	if err := store.Update(context.Background(), j); err != ErrCancelled {
+		t.Fatalf("Update after Cancel: got %v, want ErrCancelled", err)
+	}
+
+	got, err := store.Get(context.Background(), j.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.State != StateCancelled {
+		t.Errorf("job state = %q, want %q (Update must not have clobbered the cancellation)", got.State, StateCancelled)
+	}
+}
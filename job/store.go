@@ -0,0 +1,40 @@
+package job
+
+import "context"
+
+// Store persists Jobs and hands them out to workers. SQLiteStore is the
+// default and the only working implementation; the Redis/RabbitMQ stores in
+// external.go are placeholders whose Open functions always return an error.
+type Store interface {
+	// Create persists a new job in StateQueued.
+	Create(ctx context.Context, j *Job) error
+	// Get returns a job by id, or ErrNotFound.
+	Get(ctx context.Context, id string) (*Job, error)
+	// Update persists changes to an existing job (state, error, hashes, ...).
+	// It must be a no-op returning ErrCancelled if the job has already
+	// reached a terminal state (cancelled/done/failed), so a worker racing
+	// a concurrent Cancel can't overwrite it on its next stage transition.
+	Update(ctx context.Context, j *Job) error
+	// List returns all known jobs, most recently created first.
+	List(ctx context.Context) ([]*Job, error)
+	// Dequeue atomically claims the oldest StateQueued job and marks it
+	// StateGenerating, or returns ErrNoJobs if the queue is empty.
+	Dequeue(ctx context.Context) (*Job, error)
+	// Cancel marks a queued or in-flight job StateCancelled. Workers check
+	// for this between stages (via Update's ErrCancelled) and stop rather
+	// than proceeding.
+	Cancel(ctx context.Context, id string) error
+	Close() error
+}
+
+// errString is a trivial error type so this package doesn't need to import
+// "errors" just for sentinel values.
+type errString string
+
+func (e errString) Error() string { return string(e) }
+
+const (
+	ErrNotFound  errString = "job: not found"
+	ErrNoJobs    errString = "job: queue is empty"
+	ErrCancelled errString = "job: already in a terminal state"
+)
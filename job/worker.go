@@ -0,0 +1,96 @@
+package job
+
+import (
+	"context"
+	"time"
+)
+
+// Processor does the actual work for one job: generate the texture(s),
+// render through Blender, and report progress via setState as it moves
+// through StateGenerating/StateRendering. setState returns false if the job
+// has been cancelled (or otherwise reached a terminal state) since it was
+// dequeued; the Processor must stop and return immediately rather than
+// proceed to the next stage. It's supplied by the caller (see cmd_jobs.go)
+// so this package stays decoupled from the backend/render packages.
+type Processor func(ctx context.Context, j *Job, setState func(State) bool) error
+
+// Worker repeatedly dequeues jobs from a Store and runs them through
+// process, so multiple Worker instances (e.g. separate `bs-case worker`
+// processes) can fan out over the same Store and render different jobs
+// concurrently.
+type Worker struct {
+	store        Store
+	process      Processor
+	pollInterval time.Duration
+}
+
+// NewWorker builds a Worker that polls store for queued jobs every
+// pollInterval (Dequeue itself is the source of truth; polling just avoids
+// a busy loop when the queue is empty).
+func NewWorker(store Store, process Processor, pollInterval time.Duration) *Worker {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	return &Worker{store: store, process: process, pollInterval: pollInterval}
+}
+
+// Run drains the queue until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		j, err := w.store.Dequeue(ctx)
+		if err == ErrNoJobs {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(w.pollInterval):
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		w.runOne(ctx, j)
+	}
+}
+
+func (w *Worker) runOne(ctx context.Context, j *Job) {
+	cancelled := false
+	setState := func(st State) bool {
+		if cancelled {
+			return false
+		}
+		j.State = st
+		j.UpdatedAt = nowUnix()
+		if err := w.store.Update(ctx, j); err == ErrCancelled {
+			cancelled = true
+			return false
+		}
+		return true
+	}
+
+	err := w.process(ctx, j, setState)
+	if cancelled {
+		// A concurrent Cancel already won; don't clobber it with
+		// done/failed below.
+		return
+	}
+	if err != nil {
+		j.State = StateFailed
+		j.Error = err.Error()
+	} else {
+		j.State = StateDone
+	}
+	j.UpdatedAt = nowUnix()
+	w.store.Update(ctx, j)
+}
+
+// nowUnix is a seam so job timestamps don't depend on a global "current
+// time" source beyond this one call.
+var nowUnix = func() int64 { return time.Now().Unix() }
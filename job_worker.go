@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/ha5ky/bs-case/job"
+)
+
+// newJobProcessor builds the job.Processor a worker runs per job: generate
+// the job's texture with its backend, then render it through Blender (if
+// BlenderPath is set), reporting StateGenerating/StateRendering as it goes.
+// onFrame, if non-nil, is called with Blender's "frame N/M" progress as it's
+// parsed from stdout, so a caller (the HTTP server's SSE handler) can stream
+// it without re-running or re-reading the job.
+func newJobProcessor(apiKey string, onFrame func(jobID string, frame, total int)) job.Processor {
+	return func(ctx context.Context, j *job.Job, setState func(job.State) bool) error {
+		if !setState(job.StateGenerating) {
+			return nil // cancelled before generation even started
+		}
+
+		textureFile, err := os.CreateTemp("", "bs-case-job-*.png")
+		if err != nil {
+			return fmt.Errorf("failed to create temp texture file: %w", err)
+		}
+		texturePath := textureFile.Name()
+		textureFile.Close()
+		keepTexture := false
+		defer func() {
+			if !keepTexture {
+				os.Remove(texturePath)
+			}
+		}()
+
+		if _, err := generateImage(ctx, j.Backend, j.Prompt, apiKey, texturePath, "", j.AspectRatio, j.ImageSize); err != nil {
+			return fmt.Errorf("texture generation failed: %w", err)
+		}
+		if data, err := os.ReadFile(texturePath); err == nil {
+			j.ResponseHash = job.HashPrompt(string(data))
+		}
+
+		if j.BlenderPath == "" {
+			j.OutputPath = texturePath
+			keepTexture = true
+			return nil
+		}
+
+		if !setState(job.StateRendering) {
+			return nil // cancelled between generation and render
+		}
+
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get current working directory: %w", err)
+		}
+		outFile, err := os.CreateTemp("", "bs-case-job-*.mp4")
+		if err != nil {
+			return fmt.Errorf("failed to create temp output file: %w", err)
+		}
+		outputPath := outFile.Name()
+		outFile.Close()
+
+		renderReq := renderRequest{
+			blenderPath:   j.BlenderPath,
+			scriptPath:    filepath.Join(cwd, "render_script.py"),
+			modelPath:     j.ModelPath,
+			outputPath:    outputPath,
+			frames:        j.Frames,
+			rotations:     j.Rotations,
+			texturePath:   texturePath,
+			textureTarget: "front",
+		}
+		j.BlenderArgv = blenderArgs(renderReq)
+
+		var stdout io.Writer = os.Stdout
+		var tee *progressTee
+		if onFrame != nil {
+			tee = newProgressTee(os.Stdout, func(frame, total int) { onFrame(j.ID, frame, total) })
+			stdout = tee
+		}
+		renderErr := renderVideo(renderReq, stdout, os.Stderr)
+		if tee != nil {
+			tee.Close()
+		}
+		if renderErr != nil {
+			return fmt.Errorf("render failed: %w", renderErr)
+		}
+
+		j.OutputPath = outputPath
+		return nil
+	}
+}
+
+// frameProgressRe matches render_script.py's "Frame N/M" progress lines.
+var frameProgressRe = regexp.MustCompile(`(?i)frame\s+(\d+)\s*/\s*(\d+)`)
+
+// progressTee tees writes to an underlying writer while scanning them line by
+// line for "frame N/M", calling onFrame for each match. Callers must call
+// Close once the writer will see no more writes, to stop the scan goroutine.
+type progressTee struct {
+	io.Writer
+	pw *io.PipeWriter
+}
+
+func newProgressTee(w io.Writer, onFrame func(frame, total int)) *progressTee {
+	pr, pw := io.Pipe()
+	go func() {
+		scanner := bufio.NewScanner(pr)
+		for scanner.Scan() {
+			m := frameProgressRe.FindStringSubmatch(scanner.Text())
+			if m == nil {
+				continue
+			}
+			frame, _ := strconv.Atoi(m[1])
+			total, _ := strconv.Atoi(m[2])
+			onFrame(frame, total)
+		}
+	}()
+	return &progressTee{Writer: io.MultiWriter(w, pw), pw: pw}
+}
+
+func (p *progressTee) Close() error { return p.pw.Close() }
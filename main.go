@@ -4,95 +4,191 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 
-	"google.golang.org/genai"
+	"github.com/ha5ky/bs-case/backend"
+	"github.com/ha5ky/bs-case/gallery"
+	"github.com/ha5ky/bs-case/pdfinput"
+	"github.com/ha5ky/bs-case/postprocess"
 )
 
-// Nano Banana API 配置
-// 使用 Nano Banana Pro (Gemini 3 Pro Image) 以获得更高质量的纹理
-const modelName = "gemini-3-pro-image-preview"
+// varFlags collects repeated --var Key=Value flags into a map.
+type varFlags map[string]string
 
-func generateImage(ctx context.Context, prompt, apiKey, filename, imagePath, aspectRatio, imageSize string) (string, error) {
-	fmt.Printf("Generating texture with Nano Banana Pro (%s)... Prompt: %s\n", modelName, prompt)
+func (v varFlags) String() string {
+	pairs := make([]string, 0, len(v))
+	for k, val := range v {
+		pairs = append(pairs, k+"="+val)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (v varFlags) Set(s string) error {
+	key, val, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("--var must be in Key=Value form, got %q", s)
+	}
+	v[key] = val
+	return nil
+}
+
+// backendRegistry and selectedBackend are set once in main() from
+// --backend/--backends_config and used by every generateImage call, CLI or
+// HTTP. The default registry only knows about the built-in "gemini" backend.
+var (
+	backendRegistry = backend.DefaultRegistry()
+	selectedBackend = "gemini"
+	pdfPageSpec     = ""
+	pdfDPI          = 300
+	ppSpec          = ""
+)
 
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{APIKey: apiKey})
+// generateImage dials backendName (see the backend package) and asks it to
+// turn prompt (plus an optional reference image at imagePath) into a
+// texture, writing the result to filename.
+func generateImage(ctx context.Context, backendName, prompt, apiKey, filename, imagePath, aspectRatio, imageSize string) (string, error) {
+	fmt.Printf("Generating texture with backend %q... Prompt: %s\n", backendName, prompt)
+
+	b, err := backendRegistry.Dial(ctx, backendName, apiKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to create client: %w", err)
+		return "", fmt.Errorf("failed to dial backend %q: %w", backendName, err)
+	}
+	if closer, ok := b.(io.Closer); ok {
+		defer closer.Close()
 	}
 
-	var parts []*genai.Part
-	parts = append(parts, &genai.Part{Text: prompt})
+	req := &backend.GenerateTextureRequest{
+		Prompt:      prompt,
+		AspectRatio: aspectRatio,
+		Size:        imageSize,
+	}
 
 	if imagePath != "" {
 		fmt.Printf("Reading input image from: %s\n", imagePath)
-		imgData, err := os.ReadFile(imagePath)
-		if err != nil {
-			return "", fmt.Errorf("failed to read input image: %w", err)
-		}
 
-		// 简单检测图片类型，默认 png，如果是 jpg 则使用 jpeg
-		mimeType := "image/png"
-		ext := filepath.Ext(imagePath)
-		if ext == ".jpg" || ext == ".jpeg" {
-			mimeType = "image/jpeg"
-		} else if ext == ".webp" {
-			mimeType = "image/webp"
+		if strings.EqualFold(filepath.Ext(imagePath), ".pdf") {
+			pages, err := pdfinput.Rasterize(imagePath, pdfPageSpec, pdfDPI)
+			if err != nil {
+				return "", fmt.Errorf("failed to rasterize pdf input: %w", err)
+			}
+			for _, page := range pages {
+				req.RefImages = append(req.RefImages, backend.RefImage{Data: page.Data, MimeType: page.MIMEType})
+			}
+		} else {
+			imgData, err := os.ReadFile(imagePath)
+			if err != nil {
+				return "", fmt.Errorf("failed to read input image: %w", err)
+			}
+
+			// 简单检测图片类型，默认 png，如果是 jpg 则使用 jpeg
+			mimeType := "image/png"
+			ext := filepath.Ext(imagePath)
+			if ext == ".jpg" || ext == ".jpeg" {
+				mimeType = "image/jpeg"
+			} else if ext == ".webp" {
+				mimeType = "image/webp"
+			}
+
+			req.RefImages = append(req.RefImages, backend.RefImage{Data: imgData, MimeType: mimeType})
 		}
+	}
 
-		parts = append(parts, &genai.Part{
-			InlineData: &genai.Blob{
-				MIMEType: mimeType,
-				Data:     imgData,
-			},
-		})
+	resp, err := b.GenerateTexture(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate content: %w", err)
 	}
 
-	contents := []*genai.Content{
-		{Parts: parts},
+	data, err := applyPostProcess(resp.Data, filename, ppSpec)
+	if err != nil {
+		return "", fmt.Errorf("failed to post-process image: %w", err)
 	}
 
-	config := &genai.GenerateContentConfig{
-		ImageConfig: &genai.ImageConfig{
-			AspectRatio: aspectRatio,
-			ImageSize:   imageSize,
-		},
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write image file: %w", err)
 	}
 
-	resp, err := client.Models.GenerateContent(ctx, modelName, contents, config)
+	absPath, _ := filepath.Abs(filename)
+	fmt.Printf("Texture generated successfully: %s\n", absPath)
+	return absPath, nil
+}
+
+// applyPostProcess runs the --pp pipeline (see the postprocess package)
+// against the generated image bytes, re-encoding to match filename's
+// extension (.jpg/.jpeg -> JPEG, anything else -> PNG). If spec is empty,
+// data is returned unchanged.
+func applyPostProcess(data []byte, filename, spec string) ([]byte, error) {
+	steps, err := postprocess.Parse(spec)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate content: %w", err)
+		return nil, err
 	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	return postprocess.RunBytes(data, steps, ext == ".jpg" || ext == ".jpeg")
+}
 
-	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
-		return "", fmt.Errorf("no content generated")
+// generateTextureFromPDFPages rasterizes pdfPath and runs generateImage once
+// per page (--pdf_split_output), so each page of a multi-page card-art PDF
+// becomes its own texture (e.g. front_p1.png, front_p2.png, ...) for Blender
+// to consume as an animated texture atlas.
+func generateTextureFromPDFPages(ctx context.Context, backendName, prompt, apiKey, outputBase, pdfPath, aspectRatio, imageSize string) ([]string, error) {
+	pages, err := pdfinput.Rasterize(pdfPath, pdfPageSpec, pdfDPI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rasterize pdf input: %w", err)
 	}
 
-	// 假设第一个部分包含图像数据
-	part := resp.Candidates[0].Content.Parts[0]
-
-	// 检查是否有 InlineData
-	if part.InlineData == nil {
-		return "", fmt.Errorf("no inline data found in response part")
+	ext := filepath.Ext(outputBase)
+	base := strings.TrimSuffix(outputBase, ext)
+	if ext == "" {
+		ext = ".png"
 	}
 
-	// InlineData.Data 已经是 []byte (库已处理 Base64 解码)
-	imgData := part.InlineData.Data
+	var outputs []string
+	for i, page := range pages {
+		pageFile, err := os.CreateTemp("", fmt.Sprintf("bs-case-pdf-p%d-*.png", page.Number))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temp file for pdf page %d: %w", page.Number, err)
+		}
+		pagePath := pageFile.Name()
+		pageFile.Close()
+		defer os.Remove(pagePath)
+
+		if err := os.WriteFile(pagePath, page.Data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write rasterized pdf page %d: %w", page.Number, err)
+		}
 
-	// 保存文件
-	if err := os.WriteFile(filename, imgData, 0644); err != nil {
-		return "", fmt.Errorf("failed to write image file: %w", err)
+		outputPath := fmt.Sprintf("%s_p%d%s", base, i+1, ext)
+		path, err := generateImage(ctx, backendName, prompt, apiKey, outputPath, pagePath, aspectRatio, imageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate texture for pdf page %d: %w", page.Number, err)
+		}
+		outputs = append(outputs, path)
 	}
 
-	absPath, _ := filepath.Abs(filename)
-	fmt.Printf("Texture generated successfully: %s\n", absPath)
-	return absPath, nil
+	return outputs, nil
+}
+
+// jobSubcommands dispatches `bs-case enqueue|status|cancel|worker ...` to the
+// job-queue CLI (see cmd_jobs.go), leaving the original single-shot flag
+// behavior below as the default when no subcommand is given.
+var jobSubcommands = map[string]func([]string){
+	"enqueue": runEnqueue,
+	"status":  runStatus,
+	"cancel":  runCancel,
+	"worker":  runWorker,
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := jobSubcommands[os.Args[1]]; ok {
+			run(os.Args[2:])
+			return
+		}
+	}
+
 	blenderPath := flag.String("blender", "", "Path to Blender executable (optional)")
 	// projectPath := flag.String("project", "", "Path to the .blend project file (optional template)") // 暂时不需要
 	modelPath := flag.String("input", "1.glb", "Path to the 3D model file (obj, fbx, glb, etc.)")
@@ -113,8 +209,83 @@ func main() {
 	proxyAddr := flag.String("proxy", "http://127.0.0.1:7897", "Proxy address (e.g., http://127.0.0.1:7897)")
 	aspectRatio := flag.String("aspect_ratio", "1:1", "Aspect ratio for generated image (e.g., 1:1, 16:9, 4:3)")
 	imageSize := flag.String("image_size", "1K", "Image size/resolution (e.g., 1K, 2K)")
+	serve := flag.Bool("serve", false, "Start an HTTP server exposing OpenAI-compatible endpoints instead of running once")
+	addr := flag.String("addr", ":8080", "Address to listen on when --serve is set")
+	backendName := flag.String("backend", "gemini", "Image-generation backend to use (see backends.yaml)")
+	backendsConfig := flag.String("backends_config", "backends.yaml", "Path to the backend registry file")
+	templateName := flag.String("template", "", "Name of a gallery template to render instead of --prompt")
+	galleryDir := flag.String("gallery_dir", "gallery/templates", "Directory of gallery template YAML files")
+	templateVars := make(varFlags)
+	flag.Var(templateVars, "var", "Template variable in Key=Value form (repeatable)")
+	pdfPages := flag.String("pdf_pages", "", "Pages to rasterize when --image_input is a PDF, e.g. 1,3-5 (default: all pages)")
+	pdfDpiFlag := flag.Int("pdf_dpi", 300, "DPI to rasterize PDF pages at")
+	pdfSplitOutput := flag.Bool("pdf_split_output", false, "Run generation once per rasterized PDF page instead of feeding every page into one generation")
+	pp := flag.String("pp", "", `Post-processing pipeline, e.g. "resize=2048x2048,crop=card,tile=2x2,gamma=2.2,unsharp=1.0"`)
+	jobsDB := flag.String("jobs_db", "jobs.db", "Path to the sqlite jobs database (used by --serve)")
+	jobsBackend := flag.String("jobs_backend", "sqlite", "Job store backend for --serve: sqlite, redis, or rabbitmq")
 	flag.Parse()
 
+	pdfPageSpec = *pdfPages
+	pdfDPI = *pdfDpiFlag
+	ppSpec = *pp
+
+	if *templateName != "" {
+		t, err := gallery.Find([]string{*galleryDir}, *templateName)
+		if err != nil {
+			log.Fatalf("Failed to load template: %v", err)
+		}
+		if t.NeedsRefImage && *imageInput == "" {
+			log.Fatalf("Template %q requires --image_input to be set", *templateName)
+		}
+		rendered, err := t.Render(templateVars)
+		if err != nil {
+			log.Fatalf("Failed to render template %q: %v", *templateName, err)
+		}
+		*prompt = rendered
+		if t.Slot != "" {
+			*textureTarget = t.Slot
+		}
+		if t.AspectRatio != "" {
+			*aspectRatio = t.AspectRatio
+		}
+		if t.ImageSize != "" {
+			*imageSize = t.ImageSize
+		}
+		if len(t.PostProcess) > 0 {
+			ppSpec = strings.Join(t.PostProcess, ",")
+		}
+	}
+
+	selectedBackend = *backendName
+	reg, err := backend.LoadRegistry(*backendsConfig)
+	if err != nil {
+		log.Fatalf("Failed to load backend registry: %v", err)
+	}
+	if detected := reg.AutoDetect(); len(detected) > 0 {
+		fmt.Printf("Auto-detected backends on PATH: %v\n", detected)
+	}
+	backendRegistry = reg
+
+	if *serve {
+		cwd, err := os.Getwd()
+		if err != nil {
+			log.Fatalf("Failed to get current working directory: %v", err)
+		}
+		jobStore, err := openJobStore(*jobsBackend, *jobsDB, "", "")
+		if err != nil {
+			log.Fatalf("Failed to open job store: %v", err)
+		}
+		srv := &server{
+			blenderPath: *blenderPath,
+			scriptPath:  filepath.Join(cwd, "render_script.py"),
+			apiKey:      *apiKey,
+			proxyAddr:   *proxyAddr,
+			galleryDir:  *galleryDir,
+			jobStore:    jobStore,
+		}
+		log.Fatal(srv.listenAndServe(*addr))
+	}
+
 	// 设置代理
 	if *proxyAddr != "" {
 		os.Setenv("HTTP_PROXY", *proxyAddr)
@@ -161,10 +332,20 @@ func main() {
 			log.Fatal("GEMINI_API_KEY environment variable is not set. Please set it to use Nano Banana API.")
 		}
 
-		var err error
-		generatedTexturePath, err = generateImage(context.Background(), *prompt, *apiKey, *textureOutput, *imageInput, *aspectRatio, *imageSize)
-		if err != nil {
-			log.Fatalf("Failed to generate texture: %v", err)
+		if *pdfSplitOutput && strings.EqualFold(filepath.Ext(*imageInput), ".pdf") {
+			paths, err := generateTextureFromPDFPages(context.Background(), *backendName, *prompt, *apiKey, *textureOutput, *imageInput, *aspectRatio, *imageSize)
+			if err != nil {
+				log.Fatalf("Failed to generate textures from PDF pages: %v", err)
+			}
+			if len(paths) > 0 {
+				generatedTexturePath = paths[0]
+			}
+		} else {
+			var err error
+			generatedTexturePath, err = generateImage(context.Background(), *backendName, *prompt, *apiKey, *textureOutput, *imageInput, *aspectRatio, *imageSize)
+			if err != nil {
+				log.Fatalf("Failed to generate texture: %v", err)
+			}
 		}
 	}
 
@@ -214,49 +395,25 @@ func main() {
 		absTextureBackground = generatedTexturePath
 	}
 
-	// 构建参数
-	// blender --background --python render_script.py -- --input [file] --output [file] --frames [num] [--texture [file]]
-
-	args := []string{
-		"--background",
-		"--python", scriptPath,
-		"--",
-		"--input", absModelPath,
-		"--output", absOutputPath,
-		"--frames", fmt.Sprintf("%d", *frames),
-		"--rotations", fmt.Sprintf("%f", *rotations),
-	}
-
-	if absTexturePath != "" {
-		args = append(args, "--texture", absTexturePath)
-		args = append(args, "--texture_target", *textureTarget)
-	}
-
-	if absTextureFront != "" {
-		args = append(args, "--texture_front", absTextureFront)
-	}
-	if absTextureBack != "" {
-		args = append(args, "--texture_back", absTextureBack)
-	}
-	if absTextureBackground != "" {
-		args = append(args, "--texture_background", absTextureBackground)
+	renderReq := renderRequest{
+		blenderPath:       *blenderPath,
+		scriptPath:        scriptPath,
+		modelPath:         absModelPath,
+		outputPath:        absOutputPath,
+		frames:            *frames,
+		rotations:         *rotations,
+		texturePath:       absTexturePath,
+		textureTarget:     *textureTarget,
+		textureFront:      absTextureFront,
+		textureBack:       absTextureBack,
+		textureBackground: absTextureBackground,
 	}
 
 	if *blenderPath != "" {
-		cmd := exec.Command(*blenderPath, args...)
-
-		// 连接标准输出和标准错误，以便看到 Blender 的日志
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-
-		fmt.Printf("Running command: %s %v\n", *blenderPath, args)
 		fmt.Println("Rendering started... This may take a while.")
-
-		err = cmd.Run()
-		if err != nil {
+		if err := renderVideo(renderReq, os.Stdout, os.Stderr); err != nil {
 			log.Fatalf("Blender rendering failed: %v", err)
 		}
-
 		fmt.Println("Rendering finished successfully!")
 	} else {
 		fmt.Println("Blender path not specified, skipping video rendering.")
@@ -265,3 +422,63 @@ func main() {
 		}
 	}
 }
+
+// renderRequest bundles everything renderVideo needs to invoke Blender, so the
+// CLI entrypoint and the HTTP server (server.go) can share the same code path.
+type renderRequest struct {
+	blenderPath       string
+	scriptPath        string
+	modelPath         string
+	outputPath        string
+	frames            int
+	rotations         float64
+	texturePath       string
+	textureTarget     string
+	textureFront      string
+	textureBack       string
+	textureBackground string
+}
+
+// blenderArgs builds the argv renderVideo passes to Blender, split out so
+// callers that need to record what ran (e.g. Job.BlenderArgv) don't have to
+// re-derive it or run Blender twice.
+func blenderArgs(req renderRequest) []string {
+	args := []string{
+		"--background",
+		"--python", req.scriptPath,
+		"--",
+		"--input", req.modelPath,
+		"--output", req.outputPath,
+		"--frames", fmt.Sprintf("%d", req.frames),
+		"--rotations", fmt.Sprintf("%f", req.rotations),
+	}
+
+	if req.texturePath != "" {
+		args = append(args, "--texture", req.texturePath)
+		args = append(args, "--texture_target", req.textureTarget)
+	}
+	if req.textureFront != "" {
+		args = append(args, "--texture_front", req.textureFront)
+	}
+	if req.textureBack != "" {
+		args = append(args, "--texture_back", req.textureBack)
+	}
+	if req.textureBackground != "" {
+		args = append(args, "--texture_background", req.textureBackground)
+	}
+	return args
+}
+
+// renderVideo shells out to Blender with render_script.py, writing its stdout
+// and stderr to the given writers. outputPath in req is where the rendered
+// video ends up.
+func renderVideo(req renderRequest, stdout, stderr io.Writer) error {
+	args := blenderArgs(req)
+
+	cmd := exec.Command(req.blenderPath, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	fmt.Printf("Running command: %s %v\n", req.blenderPath, args)
+	return cmd.Run()
+}
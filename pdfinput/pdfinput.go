@@ -0,0 +1,162 @@
+// Package pdfinput lets bs-case accept multi-page PDF reference art (the
+// common shape card art ships in) by rasterizing selected pages to PNG so
+// they can be fed to a backend as ordinary reference images.
+package pdfinput
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Page is one rasterized PDF page.
+type Page struct {
+	Number   int
+	Data     []byte
+	MIMEType string
+}
+
+// ParsePageSpec parses a comma-separated page spec like "1,3-5" into a
+// sorted, de-duplicated list of 1-based page numbers.
+func ParsePageSpec(spec string) ([]int, error) {
+	if spec == "" {
+		return nil, nil
+	}
+
+	seen := map[int]bool{}
+	var pages []int
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if start, end, ok := strings.Cut(part, "-"); ok {
+			lo, err := strconv.Atoi(start)
+			if err != nil {
+				return nil, fmt.Errorf("invalid page range %q: %w", part, err)
+			}
+			hi, err := strconv.Atoi(end)
+			if err != nil {
+				return nil, fmt.Errorf("invalid page range %q: %w", part, err)
+			}
+			for p := lo; p <= hi; p++ {
+				if !seen[p] {
+					seen[p] = true
+					pages = append(pages, p)
+				}
+			}
+			continue
+		}
+
+		p, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page number %q: %w", part, err)
+		}
+		if !seen[p] {
+			seen[p] = true
+			pages = append(pages, p)
+		}
+	}
+
+	sort.Ints(pages)
+	return pages, nil
+}
+
+// pageNumRe extracts the page number pdftoppm embeds in each output
+// filename, e.g. "page-3.png" or zero-padded "page-03.png".
+var pageNumRe = regexp.MustCompile(`-(\d+)\.(png|jpg|jpeg)$`)
+
+// Rasterize renders the selected pages of the PDF at path to PNG at the
+// given DPI by shelling out to poppler's pdftoppm. pdfcpu (this package's
+// earlier approach) only extracts embedded raster images from a PDF, not a
+// rendered page, so a PDF with vector/text card art produced no output at
+// all; pdftoppm actually rasterizes the page. If pageSpec is empty, every
+// page is rasterized. Pages are returned in ascending page order.
+func Rasterize(path, pageSpec string, dpi int) ([]Page, error) {
+	if dpi <= 0 {
+		dpi = 300
+	}
+
+	wanted, err := ParsePageSpec(pageSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	outDir, err := os.MkdirTemp("", "bs-case-pdf-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir for pdf rasterization: %w", err)
+	}
+	defer os.RemoveAll(outDir)
+
+	args := []string{"-png", "-r", strconv.Itoa(dpi)}
+	if len(wanted) > 0 {
+		// pdftoppm only accepts a single contiguous -f/-l range; render
+		// that span and filter down to the exact requested pages below.
+		args = append(args, "-f", strconv.Itoa(wanted[0]), "-l", strconv.Itoa(wanted[len(wanted)-1]))
+	}
+	outPrefix := filepath.Join(outDir, "page")
+	args = append(args, path, outPrefix)
+
+	cmd := exec.Command("pdftoppm", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to rasterize %s at %d dpi: %w", path, dpi, err)
+	}
+
+	entries, err := os.ReadDir(outDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rasterized output: %w", err)
+	}
+
+	var wantSet map[int]bool
+	if len(wanted) > 0 {
+		wantSet = make(map[int]bool, len(wanted))
+		for _, p := range wanted {
+			wantSet[p] = true
+		}
+	}
+
+	var result []Page
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		m := pageNumRe.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		num, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+		if wantSet != nil && !wantSet[num] {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(outDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rasterized page %s: %w", entry.Name(), err)
+		}
+
+		mimeType := "image/png"
+		if ext := strings.ToLower(filepath.Ext(entry.Name())); ext == ".jpg" || ext == ".jpeg" {
+			mimeType = "image/jpeg"
+		}
+
+		result = append(result, Page{Number: num, Data: data, MIMEType: mimeType})
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("pdftoppm produced no pages for %s (page spec %q)", path, pageSpec)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Number < result[j].Number })
+	return result, nil
+}
@@ -0,0 +1,39 @@
+package pdfinput
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePageSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []int
+		wantErr bool
+	}{
+		{spec: "", want: nil},
+		{spec: "1,3-5", want: []int{1, 3, 4, 5}},
+		{spec: "5-3", want: nil},
+		{spec: "3,1,2,1", want: []int{1, 2, 3}},
+		{spec: " 2 , 4 ", want: []int{2, 4}},
+		{spec: "x", wantErr: true},
+		{spec: "1-x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePageSpec(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParsePageSpec(%q): expected error, got none", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParsePageSpec(%q): unexpected error: %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePageSpec(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+// Package postprocess implements bs-case's deterministic image pipeline:
+// a small DSL (parsed by Parse) of named steps — resize, fit, crop, tile,
+// gamma, unsharp, flip, rotate — applied to an image.Image in memory before
+// it's handed to Blender, so textures generated at the wrong size or
+// padding for a card's UV layout can be fixed up without external tools.
+package postprocess
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"github.com/disintegration/imaging"
+)
+
+// Step is one named pipeline step, e.g. "resize=2048x2048".
+type Step struct {
+	Name string
+	Arg  string
+}
+
+// Parse parses a comma-separated pipeline spec like
+// "resize=2048x2048,crop=card,tile=2x2,gamma=2.2,unsharp=1.0" into Steps, in
+// the order they should run.
+func Parse(spec string) ([]Step, error) {
+	if spec == "" {
+		return nil, nil
+	}
+
+	var steps []Step
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, arg, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid pipeline step %q: expected name=value", part)
+		}
+		steps = append(steps, Step{Name: strings.TrimSpace(name), Arg: strings.TrimSpace(arg)})
+	}
+	return steps, nil
+}
+
+// ParseList is like Parse but takes already-split step strings (e.g. a JSON
+// post_process array), each in "name=value" form.
+func ParseList(items []string) ([]Step, error) {
+	return Parse(strings.Join(items, ","))
+}
+
+// RunBytes decodes data as an image, runs steps against it, and re-encodes
+// as JPEG (if asJPEG) or PNG. It's the single entry point CLI and HTTP
+// callers both use so the decode/encode logic isn't duplicated.
+func RunBytes(data []byte, steps []Step, asJPEG bool) ([]byte, error) {
+	if len(steps) == 0 {
+		return data, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	out, err := Apply(img, steps)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if asJPEG {
+		err = jpeg.Encode(&buf, out, &jpeg.Options{Quality: 95})
+	} else {
+		err = png.Encode(&buf, out)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Apply runs steps against img in order, each operating on the previous
+// step's output, with no temp files in between.
+func Apply(img image.Image, steps []Step) (image.Image, error) {
+	out := img
+	for _, step := range steps {
+		var err error
+		out, err = applyStep(out, step)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline step %q: %w", step.Name, err)
+		}
+	}
+	return out, nil
+}
+
+func applyStep(img image.Image, step Step) (image.Image, error) {
+	switch step.Name {
+	case "resize":
+		w, h, err := parseDims(step.Arg)
+		if err != nil {
+			return nil, err
+		}
+		return imaging.Resize(img, w, h, imaging.Lanczos), nil
+
+	case "fit":
+		w, h, err := parseDims(step.Arg)
+		if err != nil {
+			return nil, err
+		}
+		return imaging.Fit(img, w, h, imaging.Lanczos), nil
+
+	case "crop":
+		if step.Arg == "card" {
+			return cropToCardBezel(img), nil
+		}
+		w, h, err := parseDims(step.Arg)
+		if err != nil {
+			return nil, err
+		}
+		return imaging.CropCenter(img, w, h), nil
+
+	case "tile":
+		cols, rows, err := parseDims(step.Arg)
+		if err != nil {
+			return nil, err
+		}
+		return tile(img, cols, rows), nil
+
+	case "gamma":
+		g, err := strconv.ParseFloat(step.Arg, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gamma %q: %w", step.Arg, err)
+		}
+		return imaging.AdjustGamma(img, g), nil
+
+	case "unsharp":
+		sigma, err := strconv.ParseFloat(step.Arg, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unsharp sigma %q: %w", step.Arg, err)
+		}
+		return imaging.Sharpen(img, sigma), nil
+
+	case "flip":
+		switch step.Arg {
+		case "h":
+			return imaging.FlipH(img), nil
+		case "v":
+			return imaging.FlipV(img), nil
+		default:
+			return nil, fmt.Errorf("invalid flip axis %q: expected h or v", step.Arg)
+		}
+
+	case "rotate":
+		deg, err := strconv.ParseFloat(step.Arg, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rotate angle %q: %w", step.Arg, err)
+		}
+		return imaging.Rotate(img, deg, image.Transparent), nil
+
+	default:
+		return nil, fmt.Errorf("unknown step name %q", step.Name)
+	}
+}
+
+// parseDims parses a "WxH" argument, e.g. "2048x2048" or "2x2".
+func parseDims(arg string) (int, int, error) {
+	wStr, hStr, ok := strings.Cut(arg, "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid dimensions %q: expected WxH", arg)
+	}
+	w, err := strconv.Atoi(wStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid width in %q: %w", arg, err)
+	}
+	h, err := strconv.Atoi(hStr)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid height in %q: %w", arg, err)
+	}
+	return w, h, nil
+}
+
+// tile repeats img into a cols x rows grid, so a single generated texture
+// can be used as a repeating pattern.
+func tile(img image.Image, cols, rows int) image.Image {
+	b := img.Bounds()
+	dst := imaging.New(b.Dx()*cols, b.Dy()*rows, image.Transparent)
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols; x++ {
+			dst = imaging.Paste(dst, img, image.Pt(x*b.Dx(), y*b.Dy()))
+		}
+	}
+	return dst
+}
+
+// cropToCardBezel auto-detects a card's bezel by finding the bounding box
+// of pixels that differ from the image's top-left pixel (taken as the
+// background colour), then crops to it. This is a simple edge/contrast heuristic, not a full
+// computer-vision bezel detector, but handles the common case of flat-color
+// padding around generated card art.
+func cropToCardBezel(img image.Image) image.Image {
+	b := img.Bounds()
+	nrgba := image.NewNRGBA(b)
+	draw.Draw(nrgba, b, img, b.Min, draw.Src)
+	bg := nrgba.NRGBAAt(b.Min.X, b.Min.Y)
+
+	const threshold = 24
+	minX, minY, maxX, maxY := b.Max.X, b.Max.Y, b.Min.X, b.Min.Y
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := nrgba.NRGBAAt(x, y)
+			if colorDelta(c, bg) > threshold {
+				if x < minX {
+					minX = x
+				}
+				if x > maxX {
+					maxX = x
+				}
+				if y < minY {
+					minY = y
+				}
+				if y > maxY {
+					maxY = y
+				}
+			}
+		}
+	}
+
+	if minX >= maxX || minY >= maxY {
+		return img
+	}
+	return imaging.Crop(img, image.Rect(minX, minY, maxX+1, maxY+1))
+}
+
+// colorDelta returns the largest per-channel absolute difference between a
+// and b, used as a cheap proxy for "these pixels look different".
+func colorDelta(a, b color.NRGBA) int {
+	delta := func(x, y uint8) int {
+		if x > y {
+			return int(x - y)
+		}
+		return int(y - x)
+	}
+	d := delta(a.R, b.R)
+	if v := delta(a.G, b.G); v > d {
+		d = v
+	}
+	if v := delta(a.B, b.B); v > d {
+		d = v
+	}
+	return d
+}
@@ -0,0 +1,59 @@
+package postprocess
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []Step
+		wantErr bool
+	}{
+		{spec: "", want: nil},
+		{
+			spec: "resize=2048x2048,gamma=2.2",
+			want: []Step{
+				{Name: "resize", Arg: "2048x2048"},
+				{Name: "gamma", Arg: "2.2"},
+			},
+		},
+		{spec: " crop = card ", want: []Step{{Name: "crop", Arg: "card"}}},
+		{spec: "resize", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q): expected error, got none", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q): unexpected error: %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseDims(t *testing.T) {
+	w, h, err := parseDims("2048x1024")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w != 2048 || h != 1024 {
+		t.Errorf("parseDims(\"2048x1024\") = (%d, %d), want (2048, 1024)", w, h)
+	}
+
+	if _, _, err := parseDims("2048"); err == nil {
+		t.Error("parseDims(\"2048\"): expected error, got none")
+	}
+	if _, _, err := parseDims("axb"); err == nil {
+		t.Error("parseDims(\"axb\"): expected error, got none")
+	}
+}
@@ -0,0 +1,573 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ha5ky/bs-case/gallery"
+	"github.com/ha5ky/bs-case/job"
+	"github.com/ha5ky/bs-case/postprocess"
+)
+
+// server implements an OpenAI-compatible HTTP API on top of generateImage and
+// renderVideo, so other services can drive bs-case without shelling out to
+// the CLI. apiKey, when set, is both the Bearer token clients must present
+// and the key forwarded to the Gemini backend (mirroring how single-tenant
+// LocalAI-style servers reuse one configured key for both purposes).
+type server struct {
+	blenderPath string
+	scriptPath  string
+	apiKey      string
+	proxyAddr   string
+	galleryDir  string
+
+	// jobStore backs /v1/jobs. When set, listenAndServe also starts an
+	// in-process worker so jobs posted to this server actually drain,
+	// without requiring a separate `bs-case worker` process.
+	jobStore job.Store
+
+	jobEventsMu sync.Mutex
+	jobEvents   map[string]chan string
+}
+
+func (s *server) listenAndServe(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/models", s.authed(s.handleModels))
+	mux.HandleFunc("/v1/images/generations", s.authed(s.handleImagesGenerations))
+	mux.HandleFunc("/v1/textures/render", s.authed(s.handleTexturesRender))
+	mux.HandleFunc("/gallery", s.authed(s.handleGallery))
+
+	if s.jobStore != nil {
+		s.jobEvents = make(map[string]chan string)
+		mux.HandleFunc("/v1/jobs", s.authed(s.handleJobs))
+		mux.HandleFunc("/v1/jobs/", s.authed(s.handleJobByID))
+
+		w := job.NewWorker(s.jobStore, newJobProcessor(s.apiKey, s.publishJobEvent), time.Second)
+		go func() {
+			if err := w.Run(context.Background()); err != nil {
+				fmt.Printf("Job worker stopped: %v\n", err)
+			}
+		}()
+	}
+
+	fmt.Printf("Listening on %s\n", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+// authed wraps a handler with Bearer auth, matching the OpenAI convention.
+// If no apiKey is configured, auth is skipped (useful for local/dev use).
+func (s *server) authed(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.apiKey == "" {
+			next(w, r)
+			return
+		}
+		header := r.Header.Get("Authorization")
+		token := strings.TrimPrefix(header, "Bearer ")
+		if token == "" || token != s.apiKey || token == header {
+			writeError(w, http.StatusUnauthorized, "invalid_api_key", "Incorrect API key provided.")
+			return
+		}
+		next(w, r)
+	}
+}
+
+type modelsResponse struct {
+	Object string      `json:"object"`
+	Data   []modelInfo `json:"data"`
+}
+
+type modelInfo struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// handleModels lists every backend in backendRegistry (gemini, plus whatever
+// backends.yaml or startup auto-detection added), mirroring how LocalAI's
+// /v1/models lists every configured model rather than just the active one.
+func (s *server) handleModels(w http.ResponseWriter, r *http.Request) {
+	names := make([]string, 0, len(backendRegistry.Backends))
+	for name := range backendRegistry.Backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	data := make([]modelInfo, 0, len(names))
+	for _, name := range names {
+		data = append(data, modelInfo{ID: name, Object: "model", OwnedBy: "bs-case"})
+	}
+
+	writeJSON(w, http.StatusOK, modelsResponse{Object: "list", Data: data})
+}
+
+type imagesGenerationsRequest struct {
+	Prompt         string   `json:"prompt"`
+	Size           string   `json:"size"`
+	N              int      `json:"n"`
+	ResponseFormat string   `json:"response_format"`
+	AspectRatio    string   `json:"aspect_ratio"`
+	PostProcess    []string `json:"post_process"`
+}
+
+type imagesGenerationsResponse struct {
+	Created int64                 `json:"created"`
+	Data    []imageGenerationData `json:"data"`
+}
+
+type imageGenerationData struct {
+	B64JSON string `json:"b64_json,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+// handleImagesGenerations wraps generateImage in an OpenAI-shaped
+// /v1/images/generations endpoint. n images are generated sequentially; the
+// API key forwarded to Gemini is the one configured on the server.
+func (s *server) handleImagesGenerations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "expected POST")
+		return
+	}
+
+	var req imagesGenerationsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	if req.Prompt == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "prompt is required")
+		return
+	}
+	if req.N <= 0 {
+		req.N = 1
+	}
+	if req.ResponseFormat == "" {
+		req.ResponseFormat = "b64_json"
+	}
+	aspectRatio := req.AspectRatio
+	if aspectRatio == "" {
+		aspectRatio = "1:1"
+	}
+	imageSize := sizeToImageSize(req.Size)
+
+	ppSteps, err := postprocess.ParseList(req.PostProcess)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	if s.proxyAddr != "" {
+		os.Setenv("HTTP_PROXY", s.proxyAddr)
+		os.Setenv("HTTPS_PROXY", s.proxyAddr)
+	}
+
+	resp := imagesGenerationsResponse{Created: 0}
+	for i := 0; i < req.N; i++ {
+		tmp, err := os.CreateTemp("", "bs-case-gen-*.png")
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+		tmpPath := tmp.Name()
+		tmp.Close()
+		defer os.Remove(tmpPath)
+
+		path, err := generateImage(context.Background(), selectedBackend, req.Prompt, s.apiKey, tmpPath, "", aspectRatio, imageSize)
+		if err != nil {
+			writeError(w, http.StatusBadGateway, "generation_failed", err.Error())
+			return
+		}
+
+		if len(ppSteps) > 0 {
+			if err := postProcessFile(path, ppSteps); err != nil {
+				writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+				return
+			}
+		}
+
+		switch req.ResponseFormat {
+		case "url":
+			resp.Data = append(resp.Data, imageGenerationData{URL: "file://" + path})
+		default:
+			data, err := os.ReadFile(path)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+				return
+			}
+			resp.Data = append(resp.Data, imageGenerationData{B64JSON: base64.StdEncoding.EncodeToString(data)})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type texturesRenderRequest struct {
+	ModelPath string `json:"model_path"`
+	Prompts   struct {
+		Front      string `json:"front"`
+		Back       string `json:"back"`
+		Background string `json:"background"`
+	} `json:"prompts"`
+	Frames      int      `json:"frames"`
+	Rotations   float64  `json:"rotations"`
+	PostProcess []string `json:"post_process"`
+}
+
+// handleTexturesRender generates any requested texture slots with the
+// selected backend, renders the model through Blender with renderVideo, and
+// streams the resulting MP4 back in the response body.
+func (s *server) handleTexturesRender(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "expected POST")
+		return
+	}
+	if s.blenderPath == "" {
+		writeError(w, http.StatusServiceUnavailable, "server_error", "server was not started with --blender")
+		return
+	}
+
+	var req texturesRenderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	if req.ModelPath == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "model_path is required")
+		return
+	}
+
+	ppSteps, err := postprocess.ParseList(req.PostProcess)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	outFile, err := os.CreateTemp("", "bs-case-render-*.mp4")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	outPath := outFile.Name()
+	outFile.Close()
+	defer os.Remove(outPath)
+
+	renderReq := renderRequest{
+		blenderPath: s.blenderPath,
+		scriptPath:  s.scriptPath,
+		modelPath:   req.ModelPath,
+		outputPath:  outPath,
+		frames:      req.Frames,
+		rotations:   req.Rotations,
+	}
+
+	slots := []struct {
+		prompt string
+		suffix string
+		dest   *string
+	}{
+		{req.Prompts.Front, ".front.png", &renderReq.textureFront},
+		{req.Prompts.Back, ".back.png", &renderReq.textureBack},
+		{req.Prompts.Background, ".background.png", &renderReq.textureBackground},
+	}
+	for _, slot := range slots {
+		if slot.prompt == "" {
+			continue
+		}
+		path, err := generateImage(context.Background(), selectedBackend, slot.prompt, s.apiKey, outPath+slot.suffix, "", "1:1", "1K")
+		if err != nil {
+			writeError(w, http.StatusBadGateway, "generation_failed", err.Error())
+			return
+		}
+		defer os.Remove(path)
+		if len(ppSteps) > 0 {
+			if err := postProcessFile(path, ppSteps); err != nil {
+				writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+				return
+			}
+		}
+		*slot.dest = path
+	}
+
+	if err := renderVideo(renderReq, os.Stdout, os.Stderr); err != nil {
+		writeError(w, http.StatusInternalServerError, "render_failed", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "video/mp4")
+	http.ServeFile(w, r, outPath)
+}
+
+// handleGallery lists installable templates from s.galleryDir. POSTing
+// {"remote": "<git url>"} fetches (or updates) a remote template pack into
+// a subdirectory of s.galleryDir first, mirroring LocalAI's model gallery.
+func (s *server) handleGallery(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		var body struct {
+			Remote string `json:"remote"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+			return
+		}
+		if body.Remote == "" {
+			writeError(w, http.StatusBadRequest, "invalid_request_error", "remote is required")
+			return
+		}
+		dest := filepath.Join(s.galleryDir, "remote", remoteCacheName(body.Remote))
+		if err := gallery.FetchRemote(body.Remote, dest); err != nil {
+			writeError(w, http.StatusBadGateway, "fetch_failed", err.Error())
+			return
+		}
+	}
+
+	templates, err := gallery.LoadDir(s.galleryDir)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"templates": templates})
+}
+
+type enqueueJobRequest struct {
+	Prompt      string  `json:"prompt"`
+	Backend     string  `json:"backend"`
+	AspectRatio string  `json:"aspect_ratio"`
+	ImageSize   string  `json:"image_size"`
+	ModelPath   string  `json:"model_path"`
+	BlenderPath string  `json:"blender_path"`
+	Frames      int     `json:"frames"`
+	Rotations   float64 `json:"rotations"`
+}
+
+// handleJobs lists jobs (GET) or enqueues a new one (POST), mirroring the
+// `bs-case enqueue` CLI subcommand for callers that would rather poll an
+// HTTP API than block on /v1/textures/render.
+func (s *server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		jobs, err := s.jobStore.List(r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"jobs": jobs})
+
+	case http.MethodPost:
+		var req enqueueJobRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+			return
+		}
+		if req.Prompt == "" {
+			writeError(w, http.StatusBadRequest, "invalid_request_error", "prompt is required")
+			return
+		}
+		if req.Backend == "" {
+			req.Backend = selectedBackend
+		}
+		if req.AspectRatio == "" {
+			req.AspectRatio = "1:1"
+		}
+		if req.ImageSize == "" {
+			req.ImageSize = "1K"
+		}
+		if req.BlenderPath == "" {
+			req.BlenderPath = s.blenderPath
+		}
+
+		now := time.Now().Unix()
+		j := &job.Job{
+			ID:          fmt.Sprintf("job_%x", time.Now().UnixNano()),
+			State:       job.StateQueued,
+			Prompt:      req.Prompt,
+			PromptHash:  job.HashPrompt(req.Prompt),
+			Backend:     req.Backend,
+			AspectRatio: req.AspectRatio,
+			ImageSize:   req.ImageSize,
+			ModelPath:   req.ModelPath,
+			BlenderPath: req.BlenderPath,
+			Frames:      req.Frames,
+			Rotations:   req.Rotations,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := s.jobStore.Create(r.Context(), j); err != nil {
+			writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, j)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "expected GET or POST")
+	}
+}
+
+// handleJobByID serves /v1/jobs/{id} (status, or DELETE to cancel) and
+// /v1/jobs/{id}/events (SSE render progress).
+func (s *server) handleJobByID(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/v1/jobs/")
+	id, sub, hasSub := strings.Cut(rest, "/")
+	if id == "" {
+		writeError(w, http.StatusNotFound, "not_found", "job id is required")
+		return
+	}
+
+	if hasSub && sub == "events" {
+		s.handleJobEvents(w, r, id)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		j, err := s.jobStore.Get(r.Context(), id)
+		if err == job.ErrNotFound {
+			writeError(w, http.StatusNotFound, "not_found", err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, j)
+
+	case http.MethodDelete:
+		if err := s.jobStore.Cancel(r.Context(), id); err == job.ErrNotFound {
+			writeError(w, http.StatusNotFound, "not_found", err.Error())
+			return
+		} else if err != nil {
+			writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "cancelled"})
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "expected GET or DELETE")
+	}
+}
+
+// handleJobEvents streams Blender's "frame N/M" progress for job id as
+// Server-Sent Events until the job reaches a terminal state.
+func (s *server) handleJobEvents(w http.ResponseWriter, r *http.Request, id string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "server_error", "streaming unsupported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	events := s.subscribeJobEvents(id)
+	defer s.unsubscribeJobEvents(id)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			j, err := s.jobStore.Get(r.Context(), id)
+			if err != nil || j.State == job.StateDone || j.State == job.StateFailed || j.State == job.StateCancelled {
+				return
+			}
+		case msg, ok := <-events:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "data: %s\n\n", msg)
+			flusher.Flush()
+		}
+	}
+}
+
+// publishJobEvent is the onFrame callback newJobProcessor calls as it parses
+// Blender's stdout; it's a no-op if nobody is subscribed to id.
+func (s *server) publishJobEvent(id string, frame, total int) {
+	s.jobEventsMu.Lock()
+	ch := s.jobEvents[id]
+	s.jobEventsMu.Unlock()
+	if ch == nil {
+		return
+	}
+	select {
+	case ch <- fmt.Sprintf("frame %d/%d", frame, total):
+	default: // drop if the subscriber isn't keeping up
+	}
+}
+
+func (s *server) subscribeJobEvents(id string) chan string {
+	ch := make(chan string, 16)
+	s.jobEventsMu.Lock()
+	s.jobEvents[id] = ch
+	s.jobEventsMu.Unlock()
+	return ch
+}
+
+func (s *server) unsubscribeJobEvents(id string) {
+	s.jobEventsMu.Lock()
+	delete(s.jobEvents, id)
+	s.jobEventsMu.Unlock()
+}
+
+// postProcessFile runs the post-processing pipeline against the image at
+// path in place, re-encoding to match its extension.
+func postProcessFile(path string, steps []postprocess.Step) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	out, err := postprocess.RunBytes(data, steps, ext == ".jpg" || ext == ".jpeg")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0644)
+}
+
+// remoteCacheName turns a git URL into a filesystem-safe directory name.
+func remoteCacheName(url string) string {
+	name := strings.NewReplacer("://", "_", "/", "_", ":", "_").Replace(url)
+	return strings.Trim(name, "_")
+}
+
+// sizeToImageSize maps an OpenAI-style "WxH" size string to the coarse
+// 1K/2K resolution buckets generateImage expects.
+func sizeToImageSize(size string) string {
+	switch size {
+	case "2048x2048", "2048x1024", "1024x2048":
+		return "2K"
+	case "":
+		return "1K"
+	default:
+		return "1K"
+	}
+}
+
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+func writeError(w http.ResponseWriter, status int, errType, message string) {
+	writeJSON(w, status, errorResponse{Error: errorBody{Message: message, Type: errType}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}